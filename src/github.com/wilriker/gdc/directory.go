@@ -0,0 +1,216 @@
+package gdc
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+// Directory is a node in a hierarchical listing, built from the flat
+// entries a recursive ListFolder call returns. It plays the same role
+// godoc's dirtrees.go plays for a filesystem: turning a flat stream of
+// paths into something that can be walked, filtered and printed as a
+// tree.
+type Directory struct {
+	Name       string
+	Path       string
+	Depth      int
+	Files      []*files.FileMetadata
+	Dirs       []*Directory
+	TotalBytes uint64
+	TotalCount int
+}
+
+// GetTree fetches a listing of root through Stream and assembles it into
+// a Directory tree, parenting entries via path.Dir(PathDisplay). The
+// listing is recursive only when l.Recursive is set; otherwise the tree
+// holds root's immediate children alone.
+func (l *Lister) GetTree(root string) (*Directory, error) {
+	root = FixPath(root)
+
+	tree := &Directory{
+		Name: directoryName(root),
+		Path: root,
+	}
+	nodes := map[string]*Directory{root: tree}
+
+	ctx := context.Background()
+	entries, errs := l.Stream(ctx, root)
+	for e := range entries {
+		parent := ensureDirectory(nodes, path.Dir(e.Path), root, tree)
+		if e.IsDir {
+			d := ensureDirectory(nodes, e.Path, root, tree)
+			d.Name = directoryName(e.Path)
+			if !containsDir(parent.Dirs, d) {
+				parent.Dirs = append(parent.Dirs, d)
+			}
+			continue
+		}
+		fm, ok := e.Metadata.(*files.FileMetadata)
+		if !ok {
+			continue
+		}
+		parent.Files = append(parent.Files, fm)
+	}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+
+	addTotals(tree)
+	return tree, nil
+}
+
+func directoryName(p string) string {
+	n := path.Base(p)
+	if n == "." || n == "/" {
+		return "/"
+	}
+	return n
+}
+
+func containsDir(dirs []*Directory, d *Directory) bool {
+	for _, e := range dirs {
+		if e == d {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureDirectory returns the Directory node for p, creating it (and any
+// parent chain back to root) if necessary.
+func ensureDirectory(nodes map[string]*Directory, p, root string, tree *Directory) *Directory {
+	if d, ok := nodes[p]; ok {
+		return d
+	}
+	if p == "." {
+		p = "/"
+	}
+	_, depth := relativeTo(root, p)
+	d := &Directory{
+		Name:  directoryName(p),
+		Path:  p,
+		Depth: depth,
+	}
+	nodes[p] = d
+	if p != root {
+		parent := ensureDirectory(nodes, path.Dir(p), root, tree)
+		if !containsDir(parent.Dirs, d) {
+			parent.Dirs = append(parent.Dirs, d)
+		}
+	}
+	return d
+}
+
+// addTotals recursively sums TotalBytes and TotalCount from the leaves up.
+func addTotals(d *Directory) (uint64, int) {
+	var bytes uint64
+	count := len(d.Files)
+	for _, f := range d.Files {
+		bytes += f.Size
+	}
+	for _, sub := range d.Dirs {
+		b, c := addTotals(sub)
+		bytes += b
+		count += c
+	}
+	d.TotalBytes = bytes
+	d.TotalCount = count
+	return bytes, count
+}
+
+// Walk calls fn for d and every directory beneath it, depth-first.
+func (d *Directory) Walk(fn func(*Directory) error) error {
+	if err := fn(d); err != nil {
+		return err
+	}
+	for _, sub := range d.Dirs {
+		if err := sub.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the first directory in the tree rooted at d for which fn
+// returns true, or nil if none match.
+func (d *Directory) Find(fn func(*Directory) bool) *Directory {
+	var found *Directory
+	d.Walk(func(cur *Directory) error {
+		if found == nil && fn(cur) {
+			found = cur
+		}
+		return nil
+	})
+	return found
+}
+
+// Filter returns a copy of the tree rooted at d containing only
+// directories (and their files) for which fn returns true. d itself is
+// always kept so the result stays rooted.
+func (d *Directory) Filter(fn func(*Directory) bool) *Directory {
+	filtered := &Directory{
+		Name:  d.Name,
+		Path:  d.Path,
+		Depth: d.Depth,
+		Files: d.Files,
+	}
+	for _, sub := range d.Dirs {
+		if fn(sub) {
+			filtered.Dirs = append(filtered.Dirs, sub.Filter(fn))
+		}
+	}
+	addTotals(filtered)
+	return filtered
+}
+
+// PrintTree renders d as an ASCII tree with per-directory totals, in the
+// same spirit as the unix "tree" command. It is meant to replace the flat
+// output of (*Lister).print() for recursive listings.
+func PrintTree(d *Directory) {
+	fmt.Println(d.Name)
+	printTree(d, "")
+}
+
+func printTree(d *Directory, prefix string) {
+	children := make([]interface{}, 0, len(d.Dirs)+len(d.Files))
+	for _, sub := range d.Dirs {
+		children = append(children, sub)
+	}
+	for _, f := range d.Files {
+		children = append(children, f)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return childName(children[i]) < childName(children[j])
+	})
+
+	for i, c := range children {
+		last := i == len(children)-1
+		branch := "├── "
+		nextPrefix := prefix + "│   "
+		if last {
+			branch = "└── "
+			nextPrefix = prefix + "    "
+		}
+		switch v := c.(type) {
+		case *Directory:
+			fmt.Printf("%s%s%s [%d files, %d bytes]\n", prefix, branch, v.Name, v.TotalCount, v.TotalBytes)
+			printTree(v, nextPrefix)
+		case *files.FileMetadata:
+			fmt.Printf("%s%s%s\n", prefix, branch, v.Name)
+		}
+	}
+}
+
+func childName(c interface{}) string {
+	switch v := c.(type) {
+	case *Directory:
+		return v.Name
+	case *files.FileMetadata:
+		return v.Name
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package gdc
+
+import (
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+// TestGetTreeDepthAgainstNonRootPath guards against ensureDirectory
+// double-counting the leading slash left after trimming a non-root root,
+// which used to report every direct child one level too deep.
+func TestGetTreeDepthAgainstNonRootPath(t *testing.T) {
+	l := newTestLister(&mockClient{
+		listFolder: func(*files.ListFolderArg) (*files.ListFolderResult, error) {
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FolderMetadata{Metadata: files.Metadata{Name: "bar", PathDisplay: "/foo/bar"}},
+					&files.FolderMetadata{Metadata: files.Metadata{Name: "baz", PathDisplay: "/foo/bar/baz"}},
+					&files.FileMetadata{Metadata: files.Metadata{Name: "qux.txt", PathDisplay: "/foo/bar/baz/qux.txt"}},
+				},
+			}, nil
+		},
+	})
+	l.Recursive = true
+
+	tree, err := l.GetTree("/foo")
+	if err != nil {
+		t.Fatalf("GetTree: %v", err)
+	}
+
+	bar := tree.Find(func(d *Directory) bool { return d.Name == "bar" })
+	if bar == nil {
+		t.Fatal("expected to find directory \"bar\"")
+	}
+	if bar.Depth != 0 {
+		t.Fatalf("bar is a direct child of root, expected Depth 0, got %d", bar.Depth)
+	}
+
+	baz := tree.Find(func(d *Directory) bool { return d.Name == "baz" })
+	if baz == nil {
+		t.Fatal("expected to find directory \"baz\"")
+	}
+	if baz.Depth != 1 {
+		t.Fatalf("baz is a grandchild of root, expected Depth 1, got %d", baz.Depth)
+	}
+
+	if len(baz.Files) != 1 || baz.Files[0].Name != "qux.txt" {
+		t.Fatalf("expected baz to contain qux.txt, got %v", baz.Files)
+	}
+}
@@ -0,0 +1,125 @@
+package gdc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Filter decides which entries a listing keeps, built from
+// Options.Include, Options.Exclude and Options.MaxDepth. It is
+// consulted by processServerResponse so filtered-out entries never make
+// it into l.paths, and filtered-out directories are never queued for the
+// worker pool to descend into.
+type Filter struct {
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+	maxDepth int
+}
+
+// newFilter compiles o.Include and o.Exclude. Patterns wrapped in
+// slashes ("/^tmp.*$/") are used as Go regexps verbatim; anything else
+// is treated as a gitignore-style glob supporting "**", "*.ext" and a
+// trailing "dir/" to match a directory and everything beneath it. Like
+// gitignore, a glob matches at any depth unless it starts with "/", in
+// which case it is anchored to the listing root.
+func newFilter(o Options) (*Filter, error) {
+	include, err := compilePatterns(o.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePatterns(o.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{include: include, exclude: exclude, maxDepth: o.MaxDepth}, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile(pattern[1 : len(pattern)-1])
+	}
+	anchored := strings.HasPrefix(pattern, "/")
+	return regexp.Compile(globToRegexp(strings.TrimPrefix(pattern, "/"), anchored))
+}
+
+// globToRegexp translates a gitignore-style glob into a regexp: "**"
+// matches any number of path segments, "*" and "?" are confined to a
+// single segment, and a trailing "/" makes the pattern also match
+// everything below the directory it names. Unless anchored, the pattern
+// is allowed to match starting at any path segment, not just the first,
+// same as an unrooted gitignore pattern.
+func globToRegexp(glob string, anchored bool) string {
+	dirOnly := strings.HasSuffix(glob, "/")
+	glob = strings.TrimSuffix(glob, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			if i+2 < len(runes) && runes[i+2] == '/' {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else {
+				b.WriteString(".*")
+				i++
+			}
+		case runes[i] == '*':
+			b.WriteString("[^/]*")
+		case runes[i] == '?':
+			b.WriteString("[^/]")
+		case strings.ContainsRune(`.+()|^$[]{}\`, runes[i]):
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			b.WriteRune(runes[i])
+		}
+	}
+	if dirOnly {
+		b.WriteString("(/.*)?$")
+	} else {
+		b.WriteString("$")
+	}
+	return b.String()
+}
+
+// Match reports whether an entry at relPath (its path relative to the
+// listing's root) and depth (0 for a direct child of the root) should be
+// kept.
+func (f *Filter) Match(relPath string, depth int) bool {
+	if f == nil {
+		return true
+	}
+	if f.maxDepth > 0 && depth > f.maxDepth {
+		return false
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(relPath) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(relPath) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package gdc
+
+import "testing"
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		maxDepth int
+		path     string
+		depth    int
+		want     bool
+	}{
+		{name: "no filters keeps everything", path: "foo/bar.txt", depth: 1, want: true},
+		{name: "exclude glob", exclude: []string{"*.tmp"}, path: "foo.tmp", depth: 0, want: false},
+		{name: "exclude dir prefix", exclude: []string{"node_modules/"}, path: "node_modules/pkg/index.js", depth: 2, want: false},
+		{name: "double star excludes any depth", exclude: []string{"**/cache/**"}, path: "a/b/cache/file", depth: 3, want: false},
+		{name: "include restricts to matches", include: []string{"*.go"}, path: "main.go", depth: 0, want: true},
+		{name: "include excludes non-matches", include: []string{"*.go"}, path: "README.md", depth: 0, want: false},
+		{name: "exclude beats include", include: []string{"*"}, exclude: []string{"secret.txt"}, path: "secret.txt", depth: 0, want: false},
+		{name: "regexp pattern", exclude: []string{"/^tmp[0-9]+$/"}, path: "tmp42", depth: 0, want: false},
+		{name: "max depth", maxDepth: 1, path: "a/b/c.txt", depth: 2, want: false},
+		{name: "unrooted glob matches below the root", exclude: []string{"*.tmp"}, path: "sub/foo.tmp", depth: 1, want: false},
+		{name: "unrooted dir glob matches below the root", exclude: []string{"node_modules/"}, path: "src/node_modules/pkg/index.js", depth: 3, want: false},
+		{name: "rooted glob only matches at the root", exclude: []string{"/*.tmp"}, path: "sub/foo.tmp", depth: 1, want: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := newFilter(Options{Include: tc.include, Exclude: tc.exclude, MaxDepth: tc.maxDepth})
+			if err != nil {
+				t.Fatalf("newFilter: %v", err)
+			}
+			if got := f.Match(tc.path, tc.depth); got != tc.want {
+				t.Errorf("Match(%q, %d) = %v, want %v", tc.path, tc.depth, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package gdc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+func TestStreamAppliesFilter(t *testing.T) {
+	l := newTestLister(&mockClient{
+		listFolder: func(*files.ListFolderArg) (*files.ListFolderResult, error) {
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FolderMetadata{Metadata: files.Metadata{Name: "node_modules", PathDisplay: "/root/node_modules"}},
+					&files.FileMetadata{Metadata: files.Metadata{Name: "leftover.txt", PathDisplay: "/root/node_modules/leftover.txt"}},
+					&files.FileMetadata{Metadata: files.Metadata{Name: "a.txt", PathDisplay: "/root/a.txt"}},
+				},
+			}, nil
+		},
+	})
+	l.Exclude = []string{"node_modules/"}
+
+	var got []string
+	entries, errs := l.Stream(context.Background(), "/root")
+	for e := range entries {
+		got = append(got, e.Path)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got) != 1 || got[0] != "/root/a.txt" {
+		t.Fatalf("expected only /root/a.txt to survive the exclude filter, got %v", got)
+	}
+}
+
+func TestStreamRetriesTransientErrors(t *testing.T) {
+	calls := 0
+	l := newTestLister(&mockClient{
+		listFolder: func(*files.ListFolderArg) (*files.ListFolderResult, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("too_many_requests/...")
+			}
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FileMetadata{Metadata: files.Metadata{Name: "a.txt", PathDisplay: "/a.txt"}},
+				},
+			}, nil
+		},
+	})
+
+	entries, errs := l.Stream(context.Background(), "/")
+	var got []string
+	for e := range entries {
+		got = append(got, e.Path)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls to ListFolder, got %d", calls)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected one entry, got %v", got)
+	}
+}
@@ -1,7 +1,9 @@
 package gdc
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"path"
 	"sort"
 	"strconv"
@@ -47,7 +49,6 @@ type Lister struct {
 	Options
 	mu    sync.Mutex
 	paths map[string]SortableMetadata
-	wg    sync.WaitGroup
 	dbx   files.Client
 }
 
@@ -66,56 +67,78 @@ func (l *Lister) List() {
 	if len(paths) == 0 {
 		paths = []string{""}
 	}
+	ctx := context.Background()
 	for _, path := range paths {
 		if l.Verbose {
 			fmt.Println("Listing files in", path, "(recursively: ", l.Recursive, ")")
 		}
-		l.GetListing(path)
+		if _, err := l.GetListing(ctx, path); err != nil {
+			fmt.Fprintln(os.Stderr, "gdc: listing", path+":", err)
+			continue
+		}
 		l.print()
 	}
 }
 
-// GetMetadata fetches metadata for a path
-func (l *Lister) GetMetadata(path string) files.IsMetadata {
-	md, err := l.dbx.GetMetadata(files.NewGetMetadataArg(FixPath(path)))
-	if err != nil {
-		panic(err)
-	}
-	return md
+// GetMetadata fetches metadata for a path, retrying transient Dropbox
+// errors according to l.Retry.
+func (l *Lister) GetMetadata(ctx context.Context, path string) (files.IsMetadata, error) {
+	var md files.IsMetadata
+	err := l.withRetry(ctx, func() error {
+		var err error
+		md, err = l.dbx.GetMetadata(files.NewGetMetadataArg(FixPath(path)))
+		return err
+	})
+	return md, err
 }
 
-// GetListing fetches the listing from Dropbox
-func (l *Lister) GetListing(path string) map[string]SortableMetadata {
-	path = FixPath(path)
-	a := files.NewListFolderArg(path)
-	a.Recursive = l.Recursive
-	r, err := l.dbx.ListFolder(a)
-	if err != nil {
-		panic(err)
-	}
-	for len(r.Entries) > 0 {
-		l.wg.Add(1)
-		go l.processServerResponse(path, r.Entries)
-		if !r.HasMore {
-			break
-		}
-		r, err = l.dbx.ListFolderContinue(files.NewListFolderContinueArg(r.Cursor))
-		if err != nil {
-			panic(err)
-		}
+// GetListing fetches the listing from Dropbox, buffering every entry into
+// l.paths. When l.Recursive is set it fans out across the bounded worker
+// pool implemented by crawl instead of following Dropbox's own recursive
+// flag, so sibling subdirectories are listed concurrently. It returns as
+// soon as ctx is cancelled, a page request exhausts its retries, or the
+// listing completes.
+func (l *Lister) GetListing(ctx context.Context, path string) (map[string]SortableMetadata, error) {
+	if err := l.crawl(ctx, FixPath(path)); err != nil {
+		return l.paths, err
 	}
-	l.wg.Wait()
-	return l.paths
+	return l.paths, nil
 }
 
-func (l *Lister) processServerResponse(path string, entries []files.IsMetadata) {
+// processServerResponse folds a page of Dropbox entries into l.paths and
+// reports which of them were subdirectories, so crawl can feed them back
+// into the pending queue. filter, matched against each entry's path
+// relative to root, decides whether an entry is kept at all; excluded
+// entries never reach l.paths and excluded directories are never
+// returned as subdirs to descend into. It checks ctx before touching
+// each entry so a cancelled listing stops appending promptly instead of
+// racing to finish.
+func (l *Lister) processServerResponse(ctx context.Context, path string, entries []files.IsMetadata, filter *Filter, root string) ([]string, error) {
+	var subdirs []string
 	for _, fi := range entries {
+		if err := ctx.Err(); err != nil {
+			return subdirs, err
+		}
+
 		var m *files.Metadata
 		switch md := fi.(type) {
 		case *files.FileMetadata:
 			m = &md.Metadata
 		case *files.FolderMetadata:
 			m = &md.Metadata
+		default:
+			// Unrecognized metadata, e.g. files.DeletedMetadata - nothing
+			// to record or descend into.
+			continue
+		}
+
+		relPath, depth := relativeTo(root, m.PathDisplay)
+		if !filter.Match(relPath, depth) {
+			continue
+		}
+
+		if _, ok := fi.(*files.FolderMetadata); ok {
+			subdirs = append(subdirs, m.PathDisplay)
 
 			// Also put the folder itself into the map when listing recursive.
 			// In case there are no files in there it would not be listed otherwise
@@ -133,7 +156,15 @@ func (l *Lister) processServerResponse(path string, entries []files.IsMetadata)
 		l.paths[filePath] = append(l.paths[filePath], fi)
 		l.mu.Unlock()
 	}
-	l.wg.Done()
+	return subdirs, nil
+}
+
+// relativeTo returns entryPath relative to root (without a leading "/")
+// and how many directory levels deep that makes it, 0 for a direct
+// child of root.
+func relativeTo(root, entryPath string) (string, int) {
+	rel := strings.TrimPrefix(strings.TrimPrefix(entryPath, root), "/")
+	return rel, strings.Count(rel, "/")
 }
 
 func (l *Lister) extractPath(md *files.Metadata) string {
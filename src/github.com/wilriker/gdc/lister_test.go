@@ -0,0 +1,135 @@
+package gdc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+// mockClient implements files.Client by embedding it as a nil interface
+// and only overriding the methods the tests below actually exercise.
+type mockClient struct {
+	files.Client
+
+	getMetadata        func(*files.GetMetadataArg) (files.IsMetadata, error)
+	listFolder         func(*files.ListFolderArg) (*files.ListFolderResult, error)
+	listFolderContinue func(*files.ListFolderContinueArg) (*files.ListFolderResult, error)
+}
+
+func (m *mockClient) GetMetadata(arg *files.GetMetadataArg) (files.IsMetadata, error) {
+	return m.getMetadata(arg)
+}
+
+func (m *mockClient) ListFolder(arg *files.ListFolderArg) (*files.ListFolderResult, error) {
+	return m.listFolder(arg)
+}
+
+func (m *mockClient) ListFolderContinue(arg *files.ListFolderContinueArg) (*files.ListFolderResult, error) {
+	return m.listFolderContinue(arg)
+}
+
+func newTestLister(dbx files.Client) *Lister {
+	l := &Lister{
+		dbx:   dbx,
+		paths: make(map[string]SortableMetadata),
+	}
+	fast := RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	l.Retry = &fast
+	return l
+}
+
+func TestGetMetadataReturnsErrorInsteadOfPanicking(t *testing.T) {
+	wantErr := errors.New("path/not_found/")
+	l := newTestLister(&mockClient{
+		getMetadata: func(*files.GetMetadataArg) (files.IsMetadata, error) {
+			return nil, wantErr
+		},
+	})
+
+	_, err := l.GetMetadata(context.Background(), "/missing")
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("got err %v, want %v", err, wantErr)
+	}
+}
+
+func TestGetListingRetriesTransientErrors(t *testing.T) {
+	calls := 0
+	l := newTestLister(&mockClient{
+		listFolder: func(*files.ListFolderArg) (*files.ListFolderResult, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("too_many_requests/...")
+			}
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FileMetadata{Metadata: files.Metadata{Name: "a.txt", PathDisplay: "/a.txt"}},
+				},
+			}, nil
+		},
+	})
+
+	paths, err := l.GetListing(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls to ListFolder, got %d", calls)
+	}
+	if len(paths["/"]) != 1 {
+		t.Fatalf("expected one entry under /, got %d", len(paths["/"]))
+	}
+}
+
+// unknownMetadata stands in for a files.IsMetadata implementation
+// processServerResponse doesn't special-case, such as
+// files.DeletedMetadata.
+type unknownMetadata struct {
+	files.IsMetadata
+}
+
+func TestProcessServerResponseSkipsUnrecognizedMetadata(t *testing.T) {
+	l := newTestLister(&mockClient{})
+
+	subdirs, err := l.processServerResponse(context.Background(), "/", []files.IsMetadata{
+		unknownMetadata{},
+		&files.FileMetadata{Metadata: files.Metadata{Name: "a.txt", PathDisplay: "/a.txt"}},
+	}, nil, "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(subdirs) != 0 {
+		t.Fatalf("expected no subdirs, got %v", subdirs)
+	}
+	if len(l.paths["/"]) != 1 {
+		t.Fatalf("expected the recognized entry to still be recorded, got %d", len(l.paths["/"]))
+	}
+}
+
+func TestGetListingStopsOnContextCancel(t *testing.T) {
+	l := newTestLister(&mockClient{
+		listFolder: func(*files.ListFolderArg) (*files.ListFolderResult, error) {
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FileMetadata{Metadata: files.Metadata{Name: "a.txt", PathDisplay: "/a.txt"}},
+				},
+				HasMore: true,
+				Cursor:  "cursor-1",
+			}, nil
+		},
+		listFolderContinue: func(*files.ListFolderContinueArg) (*files.ListFolderResult, error) {
+			t.Fatal("ListFolderContinue should not be called once the context is cancelled")
+			return nil, nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := l.GetListing(ctx, "/")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got err %v, want context.Canceled", err)
+	}
+}
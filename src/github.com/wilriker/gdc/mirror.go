@@ -0,0 +1,274 @@
+package gdc
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+// dropboxHashBlockSize is the block size Dropbox uses for its
+// content_hash algorithm: the file is hashed in 4 MiB blocks, each block
+// hash is concatenated, and the concatenation is hashed again.
+const dropboxHashBlockSize = 4 * 1024 * 1024
+
+// ChangeType classifies a single DiffEntry produced by Mirror.Diff.
+type ChangeType int
+
+// The kinds of change a Diff can report.
+const (
+	OnlyRemote ChangeType = iota
+	OnlyLocal
+	Modified
+)
+
+// DiffEntry describes one path that differs between a remote listing and
+// a local directory tree.
+type DiffEntry struct {
+	Path   string
+	Change ChangeType
+	Remote *files.FileMetadata
+	Local  string // absolute local path, empty for OnlyRemote
+}
+
+// CompareFunc decides whether a remote file and its local counterpart
+// should be considered identical. It returns true when they match, i.e.
+// no sync action is required.
+type CompareFunc func(remote *files.FileMetadata, localPath string, localInfo os.FileInfo) (bool, error)
+
+// Mirror walks a remote tree (via Lister) and a local filesystem tree in
+// parallel and computes the differences between them, the same job
+// rclone/mc's "mirror" command does. The remote side is always walked
+// recursively, regardless of Lister.Recursive - a partial remote tree
+// would make every path Mirror didn't see look OnlyLocal.
+type Mirror struct {
+	Lister  *Lister
+	Compare CompareFunc
+}
+
+// NewMirror creates a Mirror that compares by size and content_hash by
+// default.
+func NewMirror(l *Lister) *Mirror {
+	return &Mirror{Lister: l, Compare: CompareSizeAndHash}
+}
+
+// CompareSizeAndHash matches files by size first, then by recomputing the
+// Dropbox content_hash of the local file, only reading it from disk when
+// the sizes already agree.
+func CompareSizeAndHash(remote *files.FileMetadata, localPath string, localInfo os.FileInfo) (bool, error) {
+	if uint64(localInfo.Size()) != remote.Size {
+		return false, nil
+	}
+	hash, err := dropboxContentHash(localPath)
+	if err != nil {
+		return false, err
+	}
+	return hash == remote.ContentHash, nil
+}
+
+// CompareSizeAndModTime matches files by size and client_modified,
+// avoiding a local read entirely.
+func CompareSizeAndModTime(remote *files.FileMetadata, localPath string, localInfo os.FileInfo) (bool, error) {
+	if uint64(localInfo.Size()) != remote.Size {
+		return false, nil
+	}
+	return localInfo.ModTime().Equal(remote.ClientModified), nil
+}
+
+// Diff walks remotePath (recursively) and localPath and returns the
+// entries that are only-in-remote, only-in-local, or modified.
+func (m *Mirror) Diff(ctx context.Context, remotePath, localPath string) ([]DiffEntry, error) {
+	remotePath = FixPath(remotePath)
+
+	remote, err := m.remoteFiles(ctx, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	local, err := m.localFiles(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	rel := make(SortableMetadata, 0, len(remote))
+	for _, fi := range remote {
+		rel = append(rel, fi)
+	}
+	sort.Sort(rel)
+
+	var diff []DiffEntry
+	seen := make(map[string]bool, len(remote))
+	for _, fi := range rel {
+		fm := fi.(*files.FileMetadata)
+		relPath := strings.TrimPrefix(fm.PathDisplay, remotePath)
+		relPath = strings.TrimPrefix(relPath, "/")
+		seen[relPath] = true
+
+		localAbs, ok := local[relPath]
+		if !ok {
+			diff = append(diff, DiffEntry{Path: relPath, Change: OnlyRemote, Remote: fm})
+			continue
+		}
+		info, err := os.Stat(localAbs)
+		if err != nil {
+			return nil, err
+		}
+		equal, err := m.compare()(fm, localAbs, info)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			diff = append(diff, DiffEntry{Path: relPath, Change: Modified, Remote: fm, Local: localAbs})
+		}
+	}
+
+	for relPath, localAbs := range local {
+		if !seen[relPath] {
+			diff = append(diff, DiffEntry{Path: relPath, Change: OnlyLocal, Local: localAbs})
+		}
+	}
+
+	return diff, nil
+}
+
+func (m *Mirror) compare() CompareFunc {
+	if m.Compare != nil {
+		return m.Compare
+	}
+	return CompareSizeAndHash
+}
+
+// remoteFiles lists remotePath in full, regardless of m.Lister.Recursive:
+// Diff needs to see every remote file to compare against the (always
+// fully walked) local tree, so it lists through a Lister of its own with
+// Recursive forced on rather than trusting the caller's Lister to be
+// configured that way.
+func (m *Mirror) remoteFiles(ctx context.Context, remotePath string) (map[string]files.IsMetadata, error) {
+	remotePath = FixPath(remotePath)
+	result := make(map[string]files.IsMetadata)
+
+	opts := m.Lister.Options
+	opts.Recursive = true
+	recursive := &Lister{
+		Options: opts,
+		paths:   make(map[string]SortableMetadata),
+		dbx:     m.Lister.dbx,
+	}
+
+	entries, errs := recursive.Stream(ctx, remotePath)
+	for e := range entries {
+		if !e.IsDir {
+			result[e.Path] = e.Metadata
+		}
+	}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (m *Mirror) localFiles(localPath string) (map[string]string, error) {
+	result := make(map[string]string)
+	err := filepath.Walk(localPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(localPath, p)
+		if err != nil {
+			return err
+		}
+		result[filepath.ToSlash(rel)] = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Execute runs the given diff entries with up to concurrency workers in
+// parallel, calling apply for each one. Once any apply call fails,
+// Execute stops dispatching further entries - entries already running
+// are left to finish rather than cancelled - and returns the first
+// error once everything in flight has completed. Because dispatch and
+// execution overlap, a handful of entries already in flight when the
+// error occurs may still run to completion before Execute stops.
+func Execute(entries []DiffEntry, concurrency int, apply func(DiffEntry) error) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	gate := make(chan struct{}, concurrency)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	for _, e := range entries {
+		gate <- struct{}{}
+
+		mu.Lock()
+		stop := firstErr != nil
+		mu.Unlock()
+		if stop {
+			<-gate
+			break
+		}
+
+		e := e
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-gate }()
+			if err := apply(e); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// dropboxContentHash recomputes Dropbox's content_hash for a local file:
+// SHA-256 each 4 MiB block, concatenate the block digests, then SHA-256
+// the result and hex-encode it.
+func dropboxContentHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	overall := sha256.New()
+	block := make([]byte, dropboxHashBlockSize)
+	r := bufio.NewReader(f)
+	for {
+		n, err := io.ReadFull(r, block)
+		if n > 0 {
+			blockHash := sha256.Sum256(block[:n])
+			overall.Write(blockHash[:])
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(overall.Sum(nil)), nil
+}
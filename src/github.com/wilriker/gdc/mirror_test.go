@@ -0,0 +1,131 @@
+package gdc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+// TestMirrorDiffForcesRecursiveRemoteListing guards against Diff silently
+// comparing only the top-level remote listing when the Lister it was
+// built with defaults to non-recursive.
+func TestMirrorDiffForcesRecursiveRemoteListing(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("abc")
+	localFile := filepath.Join(dir, "nested", "a.txt")
+	if err := os.WriteFile(localFile, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := dropboxContentHash(localFile)
+	if err != nil {
+		t.Fatalf("dropboxContentHash: %v", err)
+	}
+
+	var gotRecursive bool
+	l := newTestLister(&mockClient{
+		listFolder: func(a *files.ListFolderArg) (*files.ListFolderResult, error) {
+			gotRecursive = a.Recursive
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FolderMetadata{Metadata: files.Metadata{Name: "nested", PathDisplay: "/root/nested"}},
+					&files.FileMetadata{
+						Metadata:    files.Metadata{Name: "a.txt", PathDisplay: "/root/nested/a.txt"},
+						Size:        uint64(len(content)),
+						ContentHash: hash,
+					},
+				},
+			}, nil
+		},
+	})
+	// l.Recursive is deliberately left at its zero value (false) - the
+	// point of the test is that Mirror ignores it.
+
+	m := NewMirror(l)
+	diff, err := m.Diff(context.Background(), "/root", dir)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	if !gotRecursive {
+		t.Fatal("Diff should force a recursive remote listing regardless of Lister.Recursive")
+	}
+	for _, d := range diff {
+		if d.Path == "nested/a.txt" {
+			t.Fatalf("nested/a.txt should have been seen and matched, not reported as %v", d)
+		}
+	}
+}
+
+// TestMirrorDiffNormalizesRemotePath guards against Diff trimming
+// fm.PathDisplay (which comes back FixPath-normalized) with the raw,
+// un-normalized remotePath argument, which would leave every relPath as
+// the full absolute path and misreport every file as both OnlyRemote and
+// OnlyLocal.
+func TestMirrorDiffNormalizesRemotePath(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("abc")
+	localFile := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(localFile, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := dropboxContentHash(localFile)
+	if err != nil {
+		t.Fatalf("dropboxContentHash: %v", err)
+	}
+
+	l := newTestLister(&mockClient{
+		listFolder: func(*files.ListFolderArg) (*files.ListFolderResult, error) {
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FileMetadata{
+						Metadata:    files.Metadata{Name: "a.txt", PathDisplay: "/root/a.txt"},
+						Size:        uint64(len(content)),
+						ContentHash: hash,
+					},
+				},
+			}, nil
+		},
+	})
+
+	m := NewMirror(l)
+	// "root" (missing the leading slash FixPath would add) should behave
+	// exactly like "/root".
+	diff, err := m.Diff(context.Background(), "root", dir)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(diff) != 0 {
+		t.Fatalf("expected a.txt to match with no diff, got %v", diff)
+	}
+}
+
+// TestExecuteStopsDispatchingAfterFirstError guards against Execute
+// running every entry regardless of earlier failures, contradicting its
+// documented first-error short circuit.
+func TestExecuteStopsDispatchingAfterFirstError(t *testing.T) {
+	wantErr := errors.New("boom")
+	var ranAfterFailure bool
+
+	entries := []DiffEntry{{Path: "a"}, {Path: "b"}, {Path: "c"}}
+	err := Execute(entries, 1, func(e DiffEntry) error {
+		if e.Path == "a" {
+			return wantErr
+		}
+		ranAfterFailure = true
+		return nil
+	})
+
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("got err %v, want %v", err, wantErr)
+	}
+	if ranAfterFailure {
+		t.Fatal("Execute should not dispatch further entries once one has failed")
+	}
+}
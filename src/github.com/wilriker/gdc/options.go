@@ -0,0 +1,37 @@
+package gdc
+
+import (
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox"
+)
+
+// Options bundles everything that controls how a Lister talks to Dropbox
+// and behaves while listing.
+type Options struct {
+	Config        *dropbox.Config
+	Paths         []string
+	Recursive     bool
+	Verbose       bool
+	HumanReadable bool
+
+	// Retry configures how failed Dropbox API calls are retried. When
+	// nil, DefaultRetryPolicy is used.
+	Retry *RetryPolicy
+
+	// Concurrency caps how many ListFolder/ListFolderContinue calls a
+	// recursive GetListing runs against Dropbox at once. Defaults to
+	// runtime.NumCPU() when left at zero.
+	Concurrency int
+
+	// Include and Exclude filter entries by path, relative to the path
+	// being listed. Each pattern is either a gitignore-style glob
+	// ("**", "*.ext", "dir/") or, wrapped in slashes, a Go regexp. An
+	// entry is kept when it matches no Exclude pattern and, if Include
+	// is non-empty, matches at least one Include pattern. Exclude takes
+	// precedence over Include.
+	Include []string
+	Exclude []string
+
+	// MaxDepth limits how many levels below the listed path are
+	// descended into; 0 means unlimited.
+	MaxDepth int
+}
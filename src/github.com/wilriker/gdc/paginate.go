@@ -0,0 +1,175 @@
+package gdc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+// pageMarker is the state ListPage needs to resume a listing: the
+// Dropbox cursor that fetches the raw page it last read from, how many
+// entries of that raw page have already been rolled up and returned, and
+// the last rolled-up common prefix it emitted. RawIndex (rather than
+// matching the last returned entry's path against a freshly-rolled-up
+// list) lets ListPage hand out pages smaller than a raw Dropbox page
+// without caring which common-prefix entries that re-roll suppresses as
+// already-seen. LastPrefix carries the common-prefix dedup state across
+// both a raw-page boundary and a maxEntries cut mid-page, since a
+// directory's children can straddle either.
+type pageMarker struct {
+	Cursor     string `json:"c,omitempty"`
+	RawIndex   int    `json:"i,omitempty"`
+	LastPrefix string `json:"p,omitempty"`
+}
+
+func encodeMarker(m pageMarker) string {
+	if m.Cursor == "" && m.RawIndex == 0 && m.LastPrefix == "" {
+		return ""
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeMarker(marker string) (pageMarker, error) {
+	var m pageMarker
+	if marker == "" {
+		return m, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(marker)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// ListPage returns up to maxEntries entries found under prefix, starting
+// after marker (an opaque string returned by a previous call to
+// ListPage, or "" to start from the beginning). When delimiter is "/",
+// ListPage never descends into subdirectories in the first place: it
+// lists prefix non-recursively, so Dropbox itself hands back each
+// subdirectory as a single folder entry, exactly like S3's ListObjects
+// and minio's treeWalk do with a delimiter - the whole subtree under
+// prefix is never pulled over the wire. Any other non-empty delimiter
+// falls back to a recursive listing rolled up client-side, since only
+// "/" lines up with Dropbox's own folder hierarchy. Passing "" as
+// delimiter returns every entry under prefix flat, regardless of depth.
+//
+// The returned nextMarker is "" once the listing is exhausted.
+func (l *Lister) ListPage(prefix, marker, delimiter string, maxEntries int) ([]files.IsMetadata, string, error) {
+	prefix = FixPath(prefix)
+
+	state, err := decodeMarker(marker)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var r *files.ListFolderResult
+	if state.Cursor == "" {
+		a := files.NewListFolderArg(prefix)
+		a.Recursive = delimiter != "/"
+		r, err = l.dbx.ListFolder(a)
+	} else {
+		r, err = l.dbx.ListFolderContinue(files.NewListFolderContinueArg(state.Cursor))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	raw := r.Entries[state.RawIndex:]
+	page, lastPrefix, consumed := rollUp(prefix, raw, delimiter, state.LastPrefix, maxEntries)
+
+	var nextMarker string
+	switch {
+	case consumed < len(raw):
+		// More raw entries left in the page we already have.
+		nextMarker = encodeMarker(pageMarker{Cursor: state.Cursor, RawIndex: state.RawIndex + consumed, LastPrefix: lastPrefix})
+	case r.HasMore:
+		nextMarker = encodeMarker(pageMarker{Cursor: r.Cursor, LastPrefix: lastPrefix})
+	}
+
+	return page, nextMarker, nil
+}
+
+// rollUp turns a raw slice of a Dropbox page into up to maxOut entries
+// ListPage should hand back for the given delimiter: unchanged (aside
+// from the maxOut cut) when delimiter is empty, or with everything below
+// the first path segment collapsed into a common-prefix folder entry
+// when delimiter is set. maxOut <= 0 means unlimited. skip is the last
+// common prefix already emitted for this listing (from an earlier call,
+// whether that left off at a raw-page boundary or a maxOut cut mid-page),
+// so a directory whose children straddle either isn't reported twice.
+//
+// rollUp returns the rolled-up entries, the last common prefix emitted
+// (for the caller to carry forward as the next skip), and how many of
+// raw were consumed producing them - which is len(raw) unless maxOut cut
+// the page short, in which case the caller resumes from raw[consumed:]
+// rather than re-rolling raw from the start.
+func rollUp(prefix string, raw []files.IsMetadata, delimiter, skip string, maxOut int) (out []files.IsMetadata, lastPrefix string, consumed int) {
+	if delimiter == "" {
+		if maxOut > 0 && len(raw) > maxOut {
+			return raw[:maxOut], "", maxOut
+		}
+		return raw, "", len(raw)
+	}
+
+	seen := make(map[string]bool)
+	if skip != "" {
+		seen[skip] = true
+	}
+	lastPrefix = skip
+
+	for i, fi := range raw {
+		if maxOut > 0 && len(out) >= maxOut {
+			return out, lastPrefix, i
+		}
+		p := entryPath(fi)
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, prefix), "/")
+		if idx := strings.Index(rel, delimiter); idx >= 0 {
+			common := prefix + "/" + rel[:idx]
+			if seen[common] {
+				continue
+			}
+			seen[common] = true
+			lastPrefix = common
+			out = append(out, commonPrefixEntry(common))
+			continue
+		}
+		out = append(out, fi)
+	}
+	return out, lastPrefix, len(raw)
+}
+
+// commonPrefixEntry builds a pseudo-folder entry standing in for
+// everything beneath a rolled-up common prefix, without Dropbox ever
+// having been asked to describe that folder directly.
+func commonPrefixEntry(p string) files.IsMetadata {
+	name := p
+	if idx := strings.LastIndex(p, "/"); idx >= 0 {
+		name = p[idx+1:]
+	}
+	return &files.FolderMetadata{
+		Metadata: files.Metadata{
+			Name:        name,
+			PathDisplay: p,
+			PathLower:   strings.ToLower(p),
+		},
+	}
+}
+
+func entryPath(fi files.IsMetadata) string {
+	switch md := fi.(type) {
+	case *files.FileMetadata:
+		return md.PathDisplay
+	case *files.FolderMetadata:
+		return md.PathDisplay
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package gdc
+
+import (
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+func TestMarkerRoundTrip(t *testing.T) {
+	want := pageMarker{Cursor: "cursor-1", RawIndex: 3, LastPrefix: "/a/sub"}
+	got, err := decodeMarker(encodeMarker(want))
+	if err != nil {
+		t.Fatalf("decodeMarker: %v", err)
+	}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	if m := encodeMarker(pageMarker{}); m != "" {
+		t.Fatalf("encodeMarker of zero value = %q, want \"\"", m)
+	}
+}
+
+func TestListPageSlashDelimiterListsNonRecursively(t *testing.T) {
+	var gotRecursive bool
+	l := newTestLister(&mockClient{
+		listFolder: func(a *files.ListFolderArg) (*files.ListFolderResult, error) {
+			gotRecursive = a.Recursive
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FolderMetadata{Metadata: files.Metadata{Name: "sub", PathDisplay: "/root/sub"}},
+					&files.FileMetadata{Metadata: files.Metadata{Name: "a.txt", PathDisplay: "/root/a.txt"}},
+				},
+			}, nil
+		},
+	})
+
+	page, next, err := l.ListPage("/root", "", "/", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotRecursive {
+		t.Fatal("ListPage with delimiter \"/\" should list non-recursively, never pulling the whole subtree")
+	}
+	if next != "" {
+		t.Fatalf("expected listing to be exhausted, got nextMarker %q", next)
+	}
+	if len(page) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(page))
+	}
+}
+
+func TestListPageEmptyDelimiterListsRecursively(t *testing.T) {
+	var gotRecursive bool
+	l := newTestLister(&mockClient{
+		listFolder: func(a *files.ListFolderArg) (*files.ListFolderResult, error) {
+			gotRecursive = a.Recursive
+			return &files.ListFolderResult{}, nil
+		},
+	})
+
+	if _, _, err := l.ListPage("/root", "", "", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !gotRecursive {
+		t.Fatal("ListPage with no delimiter should list recursively to return every entry flat")
+	}
+}
+
+// TestListPageRollupSurvivesPageBoundary guards against the common-prefix
+// pseudo-entry for one directory being emitted twice when its children are
+// split across two raw Dropbox pages.
+func TestListPageRollupSurvivesPageBoundary(t *testing.T) {
+	calls := 0
+	l := newTestLister(&mockClient{
+		listFolder: func(a *files.ListFolderArg) (*files.ListFolderResult, error) {
+			calls++
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FileMetadata{Metadata: files.Metadata{Name: "sub-a.txt", PathDisplay: "/root/sub-a.txt"}},
+				},
+				HasMore: true,
+				Cursor:  "cursor-1",
+			}, nil
+		},
+		listFolderContinue: func(*files.ListFolderContinueArg) (*files.ListFolderResult, error) {
+			calls++
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FileMetadata{Metadata: files.Metadata{Name: "sub-b.txt", PathDisplay: "/root/sub-b.txt"}},
+				},
+			}, nil
+		},
+	})
+
+	// Force the recursive+rollup path (rather than the non-recursive "/"
+	// shortcut) with a delimiter Dropbox's hierarchy doesn't line up
+	// with, so both raw pages are rolled up client-side into the same
+	// "/root/sub" common prefix.
+	page1, next, err := l.ListPage("/root", "", "-", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page1) != 1 {
+		t.Fatalf("expected a single rolled-up common-prefix entry from page 1, got %d: %v", len(page1), page1)
+	}
+	if next == "" {
+		t.Fatal("expected a nextMarker since Dropbox reported HasMore")
+	}
+
+	page2, next2, err := l.ListPage("/root", next, "-", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page2) != 0 {
+		t.Fatalf("expected the already-emitted common prefix not to be repeated, got %d: %v", len(page2), page2)
+	}
+	if next2 != "" {
+		t.Fatalf("expected the listing to be exhausted, got nextMarker %q", next2)
+	}
+	if calls != 2 {
+		t.Fatalf("expected ListFolder then ListFolderContinue once each, got %d calls", calls)
+	}
+}
+
+// TestListPageRollupSurvivesMaxEntriesTruncation guards against the
+// common-prefix pseudo-entry for one directory being emitted twice when
+// maxEntries (rather than a raw Dropbox HasMore boundary) cuts a single
+// raw page in two.
+func TestListPageRollupSurvivesMaxEntriesTruncation(t *testing.T) {
+	l := newTestLister(&mockClient{
+		listFolder: func(*files.ListFolderArg) (*files.ListFolderResult, error) {
+			return &files.ListFolderResult{
+				Entries: []files.IsMetadata{
+					&files.FileMetadata{Metadata: files.Metadata{Name: "a.txt", PathDisplay: "/root/a.txt"}},
+					&files.FileMetadata{Metadata: files.Metadata{Name: "sub-c1.txt", PathDisplay: "/root/sub-c1.txt"}},
+					&files.FileMetadata{Metadata: files.Metadata{Name: "sub-c2.txt", PathDisplay: "/root/sub-c2.txt"}},
+					&files.FileMetadata{Metadata: files.Metadata{Name: "d.txt", PathDisplay: "/root/d.txt"}},
+				},
+			}, nil
+		},
+	})
+
+	page1, next, err := l.ListPage("/root", "", "-", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page1) != 2 || entryPath(page1[0]) != "/root/a.txt" || entryPath(page1[1]) != "/root/sub" {
+		t.Fatalf("expected page 1 = [a.txt, sub], got %v", page1)
+	}
+	if next == "" {
+		t.Fatal("expected a nextMarker since maxEntries cut the raw page short")
+	}
+
+	page2, next2, err := l.ListPage("/root", next, "-", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page2) != 1 || entryPath(page2[0]) != "/root/d.txt" {
+		t.Fatalf("expected page 2 = [d.txt] with no repeat of a.txt or sub, got %v", page2)
+	}
+	if next2 != "" {
+		t.Fatalf("expected the listing to be exhausted, got nextMarker %q", next2)
+	}
+}
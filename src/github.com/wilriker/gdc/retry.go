@@ -0,0 +1,93 @@
+package gdc
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how a Lister retries a Dropbox API call that
+// failed with a transient error.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy is used whenever Options.Retry is left nil.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  200 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+func (l *Lister) retryPolicy() RetryPolicy {
+	if l.Retry != nil {
+		return *l.Retry
+	}
+	return DefaultRetryPolicy
+}
+
+// withRetry calls fn, retrying with exponential backoff and jitter while
+// fn returns a retryable error. It returns fn's last result once fn
+// succeeds, returns a non-retryable error, or the retry policy's
+// MaxRetries is exhausted, and returns ctx.Err() as soon as ctx is
+// cancelled.
+func (l *Lister) withRetry(ctx context.Context, fn func() error) error {
+	policy := l.retryPolicy()
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		err = fn()
+		if err == nil || !isRetryable(err) || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff(policy, attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// backoff computes the delay before the given (0-based) retry attempt:
+// exponential growth off BaseDelay, capped at MaxDelay, with up to 50%
+// jitter to avoid every retrying caller waking up at once.
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+	d := policy.BaseDelay << uint(attempt)
+	if d <= 0 || d > policy.MaxDelay {
+		d = policy.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
+
+// isRetryable reports whether err looks like a transient failure worth
+// retrying - Dropbox rate limiting, a 5xx response, or a network-level
+// hiccup - as opposed to a fatal API error such as a bad path or
+// malformed request.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout() || netErr.Temporary()
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, marker := range []string{"too_many_requests", "too_many_write_operations", "rate limit", "internal_server_error", "500", "502", "503", "504"} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
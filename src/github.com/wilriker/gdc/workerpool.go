@@ -0,0 +1,122 @@
+package gdc
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/files"
+)
+
+// crawl walks path with a bounded pool of workers instead of Dropbox's
+// own Recursive flag: each worker owns a queued path, lists it with its
+// own ListFolder/ListFolderContinue cursor, and - when l.Recursive is
+// set - feeds any subdirectories it finds back into the pool. A gate
+// channel caps how many of those calls run against Dropbox at once;
+// Options.Concurrency sizes it, defaulting to runtime.NumCPU().
+func (l *Lister) crawl(ctx context.Context, root string) error {
+	concurrency := l.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	gate := make(chan struct{}, concurrency)
+
+	filter, err := newFilter(l.Options)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	recordErr := func(err error) {
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+
+	var visit func(path string)
+	visit = func(path string) {
+		defer wg.Done()
+
+		select {
+		case gate <- struct{}{}:
+		case <-ctx.Done():
+			recordErr(ctx.Err())
+			return
+		}
+		subdirs, err := l.crawlOne(ctx, path, filter, root)
+		<-gate
+
+		recordErr(err)
+		if err != nil || !l.Recursive {
+			return
+		}
+		for _, d := range subdirs {
+			wg.Add(1)
+			go visit(d)
+		}
+	}
+
+	wg.Add(1)
+	go visit(root)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// crawlOne lists a single directory (non-recursively) to completion,
+// following its cursor through ListFolderContinue, and returns the
+// subdirectories it discovered along the way that filter did not
+// exclude.
+func (l *Lister) crawlOne(ctx context.Context, path string, filter *Filter, root string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	a := files.NewListFolderArg(path)
+	a.Recursive = false
+
+	var r *files.ListFolderResult
+	err := l.withRetry(ctx, func() error {
+		var err error
+		r, err = l.dbx.ListFolder(a)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var subdirs []string
+	for {
+		dirs, err := l.processServerResponse(ctx, path, r.Entries, filter, root)
+		subdirs = append(subdirs, dirs...)
+		if err != nil {
+			return subdirs, err
+		}
+
+		if !r.HasMore {
+			return subdirs, nil
+		}
+
+		cursor := r.Cursor
+		err = l.withRetry(ctx, func() error {
+			var err error
+			r, err = l.dbx.ListFolderContinue(files.NewListFolderContinueArg(cursor))
+			return err
+		})
+		if err != nil {
+			return subdirs, err
+		}
+	}
+}